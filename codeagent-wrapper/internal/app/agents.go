@@ -0,0 +1,250 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AgentInfo describes one agent prompt file discovered by ListAgents.
+type AgentInfo struct {
+	Name    string // file name without the .md extension
+	Path    string
+	Source  string // which allowed root (~/.claude, ~/.codex, ~/.codeagent/agents) it came from
+	Size    int64
+	ModTime time.Time
+	Summary string // first non-empty body line after any YAML frontmatter, "#" stripped, cut at 80 chars
+}
+
+// agentsCacheEntry holds a previous ListAgents result alongside the
+// root directory mtimes it was computed from.
+type agentsCacheEntry struct {
+	rootModTimes map[string]time.Time
+	agents       []AgentInfo
+}
+
+var (
+	agentsCacheMu sync.Mutex
+	agentsCache   *agentsCacheEntry
+)
+
+// rootModTimes stats each of roots and returns its ModTime, omitting
+// any root that doesn't exist (or isn't stattable).
+func rootModTimes(roots []string) map[string]time.Time {
+	modTimes := make(map[string]time.Time, len(roots))
+	for _, root := range roots {
+		if info, err := os.Stat(root); err == nil {
+			modTimes[root] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+func modTimesEqual(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for root, t := range a {
+		if !b[root].Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListAgents walks ~/.claude, ~/.codex, and ~/.codeagent/agents (the
+// same allow-list readAgentPromptFile enforces), enumerates *.md prompt
+// files, and returns them sorted by name. A name found under more than
+// one root keeps only its first occurrence, following allowedPromptDirs
+// precedence order. Entries whose path has a permission-denied component
+// are skipped with a warning rather than failing the whole walk; a
+// symlink is reported if broken, but filtered out if its resolved target
+// escapes the allow-list.
+//
+// The result is cached and keyed by each root directory's mtime, so
+// repeated calls (e.g. one per --prompt-include lookup) don't re-walk
+// the filesystem unless a root directory itself has changed. Because
+// the cache key is each root's own mtime, a change that doesn't add,
+// remove or rename an entry directly in a root (an edit to an existing
+// file, or a file added inside a subdirectory) is not picked up until
+// some root's mtime changes; the cache check itself is a handful of
+// stat calls.
+func ListAgents() ([]AgentInfo, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	roots := allowedPromptDirs(home)
+	modTimes := rootModTimes(roots)
+
+	agentsCacheMu.Lock()
+	if agentsCache != nil && modTimesEqual(agentsCache.rootModTimes, modTimes) {
+		cached := agentsCache.agents
+		agentsCacheMu.Unlock()
+		return cached, nil
+	}
+	agentsCacheMu.Unlock()
+
+	seen := make(map[string]bool)
+	var agents []AgentInfo
+	for _, root := range roots {
+		found, err := listAgentsUnder(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range found {
+			if seen[a.Name] {
+				continue
+			}
+			seen[a.Name] = true
+			agents = append(agents, a)
+		}
+	}
+
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+
+	agentsCacheMu.Lock()
+	agentsCache = &agentsCacheEntry{rootModTimes: modTimes, agents: agents}
+	agentsCacheMu.Unlock()
+
+	return agents, nil
+}
+
+func listAgentsUnder(root string) ([]AgentInfo, error) {
+	// Symlink targets are checked against every resolvable allowed root,
+	// matching what readAgentPromptFile accepts.
+	var resolvedAllowed []string
+	if home, herr := os.UserHomeDir(); herr == nil {
+		for _, dir := range allowedPromptDirs(home) {
+			if r, rerr := filepath.EvalSymlinks(dir); rerr == nil {
+				resolvedAllowed = append(resolvedAllowed, filepath.Clean(r))
+			}
+		}
+	}
+
+	var agents []AgentInfo
+	walkErr := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				logWarn(fmt.Sprintf("ListAgents: skipping %s: permission denied", path))
+				return nil
+			}
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if lst, lerr := os.Lstat(path); lerr == nil && lst.Mode()&os.ModeSymlink != 0 {
+			target, terr := filepath.EvalSymlinks(path)
+			if terr != nil {
+				logWarn(fmt.Sprintf("ListAgents: broken symlink %s: %v", path, terr))
+				return nil
+			}
+			within := false
+			for _, dir := range resolvedAllowed {
+				if isWithinDir(target, dir) {
+					within = true
+					break
+				}
+			}
+			if !within {
+				return nil // resolved target escapes the allow-list; filter out silently
+			}
+		}
+
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		agents = append(agents, AgentInfo{
+			Name:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Path:    path,
+			Source:  root,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Summary: readAgentSummary(path),
+		})
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return agents, nil
+}
+
+// readAgentSummary returns the first non-empty line of path's body as a
+// short preview, skipping a leading YAML frontmatter block if present.
+// Read failures yield "".
+func readAgentSummary(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	const maxLen = 80
+	for _, line := range lines[start:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if len(line) > maxLen {
+			line = line[:maxLen] + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// ResolveAgentPromptPath looks up name (without the .md extension) in
+// the agent registry built by ListAgents and returns its prompt file
+// path, so callers can write `--agent sarsh` instead of spelling out
+// `--prompt-file ~/.codeagent/agents/sarsh.md`.
+func ResolveAgentPromptPath(name string) (string, error) {
+	agents, err := ListAgents()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range agents {
+		if a.Name == name {
+			return a.Path, nil
+		}
+	}
+	return "", fmt.Errorf("agent %q not found in ~/.claude, ~/.codex, or ~/.codeagent/agents", name)
+}
+
+// runListAgentsMode implements the --list-agents CLI subcommand. The
+// os.Args dispatch loop that recognizes --list-agents and calls this
+// lives in main(), which this tree doesn't currently contain; wire the
+// flag there when that entry point is added.
+func runListAgentsMode() int {
+	agents, err := ListAgents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Listing agents failed: %v\n", err)
+		return 1
+	}
+	if len(agents) == 0 {
+		fmt.Println("No agent prompt files found in ~/.claude, ~/.codex, or ~/.codeagent/agents")
+		return 0
+	}
+	for _, a := range agents {
+		fmt.Printf("%-24s %-10s %6d bytes  %s\n", a.Name, filepath.Base(a.Source), a.Size, a.Summary)
+	}
+	return 0
+}
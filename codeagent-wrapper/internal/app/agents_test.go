@@ -0,0 +1,250 @@
+package wrapper
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func setAgentsTestHome(t *testing.T, home string) {
+	t.Helper()
+	t.Setenv("HOME", home)
+	if runtime.GOOS == "windows" {
+		t.Setenv("USERPROFILE", home)
+	}
+}
+
+func TestListAgents_FindsPromptFilesAcrossRoots(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	codexDir := filepath.Join(home, ".codex")
+	agentsDir := filepath.Join(home, ".codeagent", "agents")
+	for _, dir := range []string{claudeDir, codexDir, agentsDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, "reviewer.md"), []byte("# Reviewer\nReview code."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentsDir, "sarsh.md"), []byte("---\nname: sarsh\n---\n\nDo the thing."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(codexDir, "notes.txt"), []byte("not a prompt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	if len(agents) != 2 {
+		t.Fatalf("expected 2 agents, got %d: %+v", len(agents), agents)
+	}
+	if agents[0].Name != "reviewer" || agents[1].Name != "sarsh" {
+		t.Fatalf("expected sorted [reviewer, sarsh], got [%s, %s]", agents[0].Name, agents[1].Name)
+	}
+	if agents[0].Summary != "Reviewer" {
+		t.Errorf("expected summary %q, got %q", "Reviewer", agents[0].Summary)
+	}
+	if agents[1].Summary != "Do the thing." {
+		t.Errorf("expected summary %q, got %q", "Do the thing.", agents[1].Summary)
+	}
+}
+
+func TestListAgents_NameCollisionKeepsFirstRoot(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	codexDir := filepath.Join(home, ".codex")
+	os.MkdirAll(claudeDir, 0o755)
+	os.MkdirAll(codexDir, 0o755)
+	os.WriteFile(filepath.Join(claudeDir, "dup.md"), []byte("claude version"), 0o644)
+	os.WriteFile(filepath.Join(codexDir, "dup.md"), []byte("codex version"), 0o644)
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 deduplicated agent, got %d: %+v", len(agents), agents)
+	}
+	if agents[0].Source != claudeDir {
+		t.Fatalf("expected ~/.claude to win the collision, got source %q", agents[0].Source)
+	}
+}
+
+func TestListAgents_NoRootsExist(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	if len(agents) != 0 {
+		t.Fatalf("expected no agents, got %+v", agents)
+	}
+}
+
+func TestListAgents_PermissionDeniedEntryIsSkippedNotFatal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("chmod-based permission test is not reliable on Windows")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	os.WriteFile(filepath.Join(claudeDir, "visible.md"), []byte("visible"), 0o644)
+
+	blockedDir := filepath.Join(claudeDir, "blocked")
+	os.MkdirAll(blockedDir, 0o755)
+	os.WriteFile(filepath.Join(blockedDir, "hidden.md"), []byte("hidden"), 0o644)
+	if err := os.Chmod(blockedDir, 0o000); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	defer os.Chmod(blockedDir, 0o755)
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	found := false
+	for _, a := range agents {
+		if a.Name == "visible" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the walk to continue past the permission-denied dir, got %+v", agents)
+	}
+}
+
+func TestListAgents_BrokenSymlinkReportedNotFatal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not reliably creatable on Windows without elevation")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	os.WriteFile(filepath.Join(claudeDir, "ok.md"), []byte("ok"), 0o644)
+
+	if err := os.Symlink(filepath.Join(claudeDir, "does-not-exist.md"), filepath.Join(claudeDir, "broken.md")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	found := false
+	for _, a := range agents {
+		if a.Name == "ok" {
+			found = true
+		}
+		if a.Name == "broken" {
+			t.Errorf("broken symlink should not be listed as a resolvable agent, got %+v", a)
+		}
+	}
+	if !found {
+		t.Fatalf("expected the walk to continue past the broken symlink, got %+v", agents)
+	}
+}
+
+func TestListAgents_SymlinkEscapingAllowListIsFiltered(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not reliably creatable on Windows without elevation")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+
+	outside := t.TempDir()
+	secretPath := filepath.Join(outside, "secret.md")
+	if err := os.WriteFile(secretPath, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secretPath, filepath.Join(claudeDir, "escape.md")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	for _, a := range agents {
+		if a.Name == "escape" {
+			t.Fatalf("expected symlink escaping the allow-list to be filtered out, got %+v", a)
+		}
+	}
+}
+
+func TestListAgents_CachesUntilRootMtimeChanges(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	os.WriteFile(filepath.Join(claudeDir, "first.md"), []byte("first"), 0o644)
+
+	agents, err := ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 agent, got %d: %+v", len(agents), agents)
+	}
+
+	// Adding a new file bumps claudeDir's own mtime, so the cache must
+	// be invalidated and the new file picked up.
+	if err := os.WriteFile(filepath.Join(claudeDir, "second.md"), []byte("second"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, err = ListAgents()
+	if err != nil {
+		t.Fatalf("ListAgents (after add): %v", err)
+	}
+	if len(agents) != 2 {
+		t.Fatalf("expected the cache to be invalidated by the new file, got %d agents: %+v", len(agents), agents)
+	}
+}
+
+func TestResolveAgentPromptPath_NotFound(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	if _, err := ResolveAgentPromptPath("nonexistent-agent"); err == nil {
+		t.Fatal("expected an error for an unregistered agent name")
+	}
+}
+
+func TestResolveAgentPromptPath_Found(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	agentsDir := filepath.Join(home, ".codeagent", "agents")
+	os.MkdirAll(agentsDir, 0o755)
+	path := filepath.Join(agentsDir, "sarsh.md")
+	os.WriteFile(path, []byte("Do the thing."), 0o644)
+
+	got, err := ResolveAgentPromptPath("sarsh")
+	if err != nil {
+		t.Fatalf("ResolveAgentPromptPath: %v", err)
+	}
+	if got != path {
+		t.Fatalf("got %q, want %q", got, path)
+	}
+}
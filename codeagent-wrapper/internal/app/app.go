@@ -111,7 +111,49 @@ func runCleanupMode() int {
 	return 0
 }
 
+// allowedPromptDirs is the allow-list readAgentPromptFile and ListAgents
+// both search: ~/.claude, ~/.codex, and ~/.codeagent/agents, in that
+// order.
+func allowedPromptDirs(home string) []string {
+	allowedDirs := []string{
+		filepath.Clean(filepath.Join(home, ".claude")),
+		filepath.Clean(filepath.Join(home, ".codex")),
+		filepath.Clean(filepath.Join(home, ".codeagent", "agents")),
+	}
+	for i := range allowedDirs {
+		allowedAbs, err := filepath.Abs(allowedDirs[i])
+		if err == nil {
+			allowedDirs[i] = filepath.Clean(allowedAbs)
+		}
+	}
+	return allowedDirs
+}
+
+// isWithinDir reports whether path is dir itself or a descendant of it.
+func isWithinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	rel = filepath.Clean(rel)
+	if rel == "." {
+		return true
+	}
+	if rel == ".." {
+		return false
+	}
+	prefix := ".." + string(os.PathSeparator)
+	return !strings.HasPrefix(rel, prefix)
+}
+
 func readAgentPromptFile(path string, allowOutsideClaudeDir bool) (string, error) {
+	return readAgentPromptFileWithOptions(path, ReadAgentPromptFileOptions{
+		AllowOutsideClaudeDir: allowOutsideClaudeDir,
+		SymlinkPolicy:         symlinkPolicyFromEnv(),
+	})
+}
+
+func readAgentPromptFileWithOptions(path string, opts ReadAgentPromptFileOptions) (string, error) {
 	raw := strings.TrimSpace(path)
 	if raw == "" {
 		return "", nil
@@ -136,42 +178,22 @@ func readAgentPromptFile(path string, allowOutsideClaudeDir bool) (string, error
 	}
 	absPath = filepath.Clean(absPath)
 
+	policy := opts.SymlinkPolicy
+	if policy == SymlinkFollowAny && !opts.AllowOutsideClaudeDir {
+		logWarn("symlink-policy follow-any requires allowOutsideClaudeDir; falling back to allow-within-allowed")
+		policy = SymlinkAllowWithinAllowed
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
-		if !allowOutsideClaudeDir {
+		if !opts.AllowOutsideClaudeDir {
 			return "", err
 		}
 		logWarn(fmt.Sprintf("Failed to resolve home directory for prompt file validation: %v; proceeding without restriction", err))
 	} else {
-		allowedDirs := []string{
-			filepath.Clean(filepath.Join(home, ".claude")),
-			filepath.Clean(filepath.Join(home, ".codex")),
-			filepath.Clean(filepath.Join(home, ".codeagent", "agents")),
-		}
-		for i := range allowedDirs {
-			allowedAbs, err := filepath.Abs(allowedDirs[i])
-			if err == nil {
-				allowedDirs[i] = filepath.Clean(allowedAbs)
-			}
-		}
-
-		isWithinDir := func(path, dir string) bool {
-			rel, err := filepath.Rel(dir, path)
-			if err != nil {
-				return false
-			}
-			rel = filepath.Clean(rel)
-			if rel == "." {
-				return true
-			}
-			if rel == ".." {
-				return false
-			}
-			prefix := ".." + string(os.PathSeparator)
-			return !strings.HasPrefix(rel, prefix)
-		}
+		allowedDirs := allowedPromptDirs(home)
 
-		if !allowOutsideClaudeDir {
+		if !opts.AllowOutsideClaudeDir {
 			withinAllowed := false
 			for _, dir := range allowedDirs {
 				if isWithinDir(absPath, dir) {
@@ -184,30 +206,8 @@ func readAgentPromptFile(path string, allowOutsideClaudeDir bool) (string, error
 				return "", fmt.Errorf("prompt file must be under ~/.claude, ~/.codex, or ~/.codeagent/agents")
 			}
 
-			resolvedPath, errPath := filepath.EvalSymlinks(absPath)
-			if errPath == nil {
-				resolvedPath = filepath.Clean(resolvedPath)
-				resolvedAllowed := make([]string, 0, len(allowedDirs))
-				for _, dir := range allowedDirs {
-					resolvedBase, errBase := filepath.EvalSymlinks(dir)
-					if errBase != nil {
-						continue
-					}
-					resolvedAllowed = append(resolvedAllowed, filepath.Clean(resolvedBase))
-				}
-				if len(resolvedAllowed) > 0 {
-					withinResolved := false
-					for _, dir := range resolvedAllowed {
-						if isWithinDir(resolvedPath, dir) {
-							withinResolved = true
-							break
-						}
-					}
-					if !withinResolved {
-						logWarn(fmt.Sprintf("Refusing to read prompt file outside allowed dirs (%s) (resolved): %s", strings.Join(resolvedAllowed, ", "), resolvedPath))
-						return "", fmt.Errorf("prompt file must be under ~/.claude, ~/.codex, or ~/.codeagent/agents")
-					}
-				}
+			if err := checkSymlinkPolicy(policy, absPath, allowedDirs); err != nil {
+				return "", err
 			}
 		} else {
 			withinAllowed := false
@@ -220,6 +220,14 @@ func readAgentPromptFile(path string, allowOutsideClaudeDir bool) (string, error
 			if !withinAllowed {
 				logWarn(fmt.Sprintf("Reading prompt file outside allowed dirs (%s): %s", strings.Join(allowedDirs, ", "), absPath))
 			}
+
+			// SymlinkDeny is defense-in-depth and applies even when
+			// reading outside the allow-list is otherwise permitted.
+			if policy == SymlinkDeny {
+				if err := checkSymlinkPolicy(policy, absPath, allowedDirs); err != nil {
+					return "", err
+				}
+			}
 		}
 	}
 
@@ -251,11 +259,15 @@ Usage:
     %[1]s "task" [workdir]
     %[1]s --backend claude "task" [workdir]
     %[1]s --prompt-file /path/to/prompt.md "task" [workdir]
+    %[1]s --prompt-file a.md --prompt-file b.md "task" [workdir]   Compose multiple prompt files
+    %[1]s --prompt-include sarsh "task" [workdir]                  Compose by agent registry name
     %[1]s - [workdir]              Read task from stdin
     %[1]s resume <session_id> "task" [workdir]
     %[1]s resume <session_id> - [workdir]
     %[1]s --parallel               Run tasks in parallel (config from stdin)
     %[1]s --parallel --full-output Run tasks in parallel with full output (legacy)
+    %[1]s --list-agents            List discovered agent prompt files (~/.claude, ~/.codex, ~/.codeagent/agents)
+    %[1]s --preflight              Check log/workdir writability, backend binary, and prompt-file readability
     %[1]s --version
     %[1]s --help
 
@@ -266,12 +278,15 @@ Parallel mode examples:
     %[1]s --parallel <<'EOF'
 
 Environment Variables:
-    CODEX_TIMEOUT         Timeout in milliseconds (default: 7200000)
-    CODEAGENT_ASCII_MODE  Use ASCII symbols instead of Unicode (PASS/WARN/FAIL)
+    CODEX_TIMEOUT            Timeout in milliseconds (default: 7200000)
+    CODEAGENT_ASCII_MODE     Use ASCII symbols instead of Unicode (PASS/WARN/FAIL)
+    CODEAGENT_SYMLINK_POLICY Prompt-file symlink handling: deny, allow-within-allowed
+                             (default), or follow-any
 
 Exit Codes:
     0    Success
     1    General error (missing args, no output)
+    77   Log dir or workdir is not writable, or a --preflight check failed (EX_NOPERM)
     124  Timeout
     127  backend command not found
     130  Interrupted (Ctrl+C)
@@ -0,0 +1,110 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxComposedPromptFiles caps how many --prompt-file/--prompt-include
+	// sources ComposeAgentPrompts will accept in one invocation.
+	maxComposedPromptFiles = 16
+	// maxComposedPromptBytes caps the combined size of every resolved
+	// prompt file, so a handful of large agent prompts can't blow up the
+	// final task text.
+	maxComposedPromptBytes = 512 * 1024
+)
+
+// expandPromptPath applies the same "~" expansion and Abs/Clean
+// normalization readAgentPromptFileWithOptions uses, so callers that
+// need to compare paths (e.g. for de-duplication) agree with it on what
+// a given prompt-file argument resolves to.
+func expandPromptPath(path string) (string, error) {
+	raw := strings.TrimSpace(path)
+	expanded := raw
+	if raw == "~" || strings.HasPrefix(raw, "~/") || strings.HasPrefix(raw, "~\\") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		if raw == "~" {
+			expanded = home
+		} else {
+			expanded = home + raw[1:]
+		}
+	}
+	absPath, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(absPath), nil
+}
+
+// dedupKeyForPromptPath returns the key ComposeAgentPrompts uses to spot
+// duplicate sources: the symlink-resolved absolute path when resolution
+// succeeds, otherwise the plain absolute path.
+func dedupKeyForPromptPath(path string) (string, error) {
+	absPath, err := expandPromptPath(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return filepath.Clean(resolved), nil
+	}
+	return absPath, nil
+}
+
+// ComposeAgentPrompts resolves each of paths through readAgentPromptFile
+// (so every source is subject to the same allow-list checks a single
+// --prompt-file argument would be), de-duplicates sources that resolve
+// to the same absolute path, wraps each one's content in an
+// `<agent-prompt src="...">` block, and joins the blocks with blank
+// lines before task. Sources are processed in order, and ordering is
+// preserved in the composed result. An empty paths list returns task
+// unchanged.
+func ComposeAgentPrompts(paths []string, task string) (string, error) {
+	if len(paths) == 0 {
+		return task, nil
+	}
+	if len(paths) > maxComposedPromptFiles {
+		return "", fmt.Errorf("too many prompt files: %d exceeds the limit of %d", len(paths), maxComposedPromptFiles)
+	}
+
+	seen := make(map[string]bool)
+	var blocks []string
+	total := 0
+	for _, raw := range paths {
+		path := strings.TrimSpace(raw)
+		if path == "" {
+			continue
+		}
+
+		key, err := dedupKeyForPromptPath(path)
+		if err != nil {
+			return "", err
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		content, err := readAgentPromptFile(path, false)
+		if err != nil {
+			return "", fmt.Errorf("prompt file %q: %w", path, err)
+		}
+
+		total += len(content)
+		if total > maxComposedPromptBytes {
+			return "", fmt.Errorf("combined prompt files exceed %d bytes", maxComposedPromptBytes)
+		}
+
+		blocks = append(blocks, fmt.Sprintf("<agent-prompt src=%q>\n%s\n</agent-prompt>", path, content))
+	}
+
+	if len(blocks) == 0 {
+		return task, nil
+	}
+	return strings.Join(blocks, "\n\n") + "\n\n" + task, nil
+}
@@ -0,0 +1,125 @@
+package wrapper
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestComposeAgentPrompts_NoFilesReturnsTaskUnchanged(t *testing.T) {
+	got, err := ComposeAgentPrompts(nil, "do the thing")
+	if err != nil {
+		t.Fatalf("ComposeAgentPrompts: %v", err)
+	}
+	if got != "do the thing" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestComposeAgentPrompts_OrderingAndWrapping(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	first := filepath.Join(claudeDir, "first.md")
+	second := filepath.Join(claudeDir, "second.md")
+	os.WriteFile(first, []byte("First prompt."), 0o644)
+	os.WriteFile(second, []byte("Second prompt."), 0o644)
+
+	got, err := ComposeAgentPrompts([]string{first, second}, "task text")
+	if err != nil {
+		t.Fatalf("ComposeAgentPrompts: %v", err)
+	}
+
+	firstIdx := strings.Index(got, "First prompt.")
+	secondIdx := strings.Index(got, "Second prompt.")
+	taskIdx := strings.Index(got, "task text")
+	if firstIdx == -1 || secondIdx == -1 || taskIdx == -1 {
+		t.Fatalf("expected all three sections present, got %q", got)
+	}
+	if !(firstIdx < secondIdx && secondIdx < taskIdx) {
+		t.Fatalf("expected first < second < task ordering, got %q", got)
+	}
+	if !strings.Contains(got, `<agent-prompt src="`+first+`">`) {
+		t.Fatalf("expected an agent-prompt block tagged with src, got %q", got)
+	}
+}
+
+func TestComposeAgentPrompts_DeduplicatesSamePathViaSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not reliably creatable on Windows without elevation")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	real := filepath.Join(claudeDir, "real.md")
+	os.WriteFile(real, []byte("Only once."), 0o644)
+	link := filepath.Join(claudeDir, "alias.md")
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	got, err := ComposeAgentPrompts([]string{real, link}, "task")
+	if err != nil {
+		t.Fatalf("ComposeAgentPrompts: %v", err)
+	}
+	if strings.Count(got, "Only once.") != 1 {
+		t.Fatalf("expected the duplicate (via symlink) source to be collapsed, got %q", got)
+	}
+}
+
+func TestComposeAgentPrompts_PerFileRejectionUnderRestrictedMode(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	allowed := filepath.Join(claudeDir, "allowed.md")
+	os.WriteFile(allowed, []byte("Allowed."), 0o644)
+
+	outside := t.TempDir()
+	disallowed := filepath.Join(outside, "disallowed.md")
+	os.WriteFile(disallowed, []byte("Disallowed."), 0o644)
+
+	if _, err := ComposeAgentPrompts([]string{allowed, disallowed}, "task"); err == nil {
+		t.Fatal("expected the out-of-allow-list source to be rejected")
+	}
+}
+
+func TestComposeAgentPrompts_SizeCap(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+
+	big := strings.Repeat("x", maxComposedPromptBytes)
+	a := filepath.Join(claudeDir, "a.md")
+	b := filepath.Join(claudeDir, "b.md")
+	os.WriteFile(a, []byte(big), 0o644)
+	os.WriteFile(b, []byte(big), 0o644)
+
+	if _, err := ComposeAgentPrompts([]string{a, b}, "task"); err == nil {
+		t.Fatal("expected combined prompt files over the size cap to be rejected")
+	}
+}
+
+func TestComposeAgentPrompts_TooManyFiles(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	paths := make([]string, maxComposedPromptFiles+1)
+	for i := range paths {
+		paths[i] = filepath.Join(home, ".claude", "nonexistent.md")
+	}
+
+	if _, err := ComposeAgentPrompts(paths, "task"); err == nil {
+		t.Fatal("expected more than the file-count cap to be rejected")
+	}
+}
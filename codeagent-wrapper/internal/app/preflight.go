@@ -0,0 +1,155 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// EXNoPerm is returned by preflightPaths when a required directory is
+// not writable, and by the --preflight subcommand when any of its checks
+// fails (including a missing backend binary). It mirrors the BSD
+// sysexits.h EX_NOPERM code.
+const EXNoPerm = 77
+
+// defaultLogDir returns ~/.codeagent/logs, the directory runStartupCleanup
+// and preflightPaths both expect to be writable.
+func defaultLogDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".codeagent", "logs"), nil
+}
+
+// checkWriteable attempts to create and remove a small marker file in
+// dir, the simplest reliable way to confirm the directory accepts
+// writes from this process.
+func checkWriteable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	probe := filepath.Join(dir, fmt.Sprintf(".codeagent-writetest-%d", os.Getpid()))
+	f, err := os.Create(probe)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(probe)
+}
+
+// preflightPaths verifies logDir and workDir are writable before
+// runTaskFn starts, so a permission problem surfaces as a single clear
+// error instead of failing partway through a task run. It returns
+// EXNoPerm and a descriptive error on the first directory that fails.
+func preflightPaths(logDir, workDir string) (int, error) {
+	dirs := []struct {
+		label string
+		path  string
+	}{
+		{"log dir", logDir},
+		{"workdir", workDir},
+	}
+	for _, d := range dirs {
+		if err := checkWriteable(d.path); err != nil {
+			if os.IsPermission(err) {
+				return EXNoPerm, fmt.Errorf("%s %s is not writable: permission denied", d.label, d.path)
+			}
+			return EXNoPerm, fmt.Errorf("%s %s is not writable: %w", d.label, d.path, err)
+		}
+	}
+	return 0, nil
+}
+
+// preflightStatus is the outcome of a single preflight check.
+type preflightStatus string
+
+const (
+	preflightPass preflightStatus = "PASS"
+	preflightWarn preflightStatus = "WARN"
+	preflightFail preflightStatus = "FAIL"
+)
+
+// preflightCheck is one row of the --preflight report.
+type preflightCheck struct {
+	name   string
+	status preflightStatus
+	detail string
+}
+
+func checkDirWriteable(name, dir string) preflightCheck {
+	if err := checkWriteable(dir); err != nil {
+		if os.IsPermission(err) {
+			return preflightCheck{name, preflightFail, fmt.Sprintf("%s is not writable: permission denied", dir)}
+		}
+		return preflightCheck{name, preflightFail, fmt.Sprintf("%s is not writable: %v", dir, err)}
+	}
+	return preflightCheck{name, preflightPass, dir}
+}
+
+func checkBackendBinary(backend string) preflightCheck {
+	path, err := exec.LookPath(backend)
+	if err != nil {
+		return preflightCheck{"backend binary", preflightFail, fmt.Sprintf("%q not found in PATH", backend)}
+	}
+	return preflightCheck{"backend binary", preflightPass, path}
+}
+
+func checkPromptFileReadable(path string) preflightCheck {
+	if _, err := readAgentPromptFile(path, false); err != nil {
+		return preflightCheck{"prompt file " + path, preflightWarn, err.Error()}
+	}
+	return preflightCheck{"prompt file " + path, preflightPass, "readable"}
+}
+
+// preflightSymbol renders status honoring CODEAGENT_ASCII_MODE: plain
+// PASS/WARN/FAIL text in ASCII mode, a Unicode glyph prefix otherwise.
+func preflightSymbol(status preflightStatus) string {
+	if os.Getenv("CODEAGENT_ASCII_MODE") != "" {
+		return string(status)
+	}
+	switch status {
+	case preflightPass:
+		return "✓ PASS"
+	case preflightWarn:
+		return "⚠ WARN"
+	default:
+		return "✗ FAIL"
+	}
+}
+
+func printPreflightTable(checks []preflightCheck) {
+	for _, c := range checks {
+		fmt.Printf("%-8s %-20s %s\n", preflightSymbol(c.status), c.name, c.detail)
+	}
+}
+
+// runPreflightMode implements the --preflight CLI subcommand: it checks
+// writability of logDir/workDir, existence of the backend binary on
+// PATH, and readability of every prompt file, then prints a PASS/WARN/
+// FAIL table. It returns EXNoPerm if any check failed, 0 otherwise. As
+// with runListAgentsMode, the os.Args dispatch that recognizes
+// --preflight and calls this with the resolved logDir/workDir/backend/
+// prompt files lives in main(), which isn't present in this tree yet.
+func runPreflightMode(logDir, workDir, backend string, promptFiles []string) int {
+	var checks []preflightCheck
+	checks = append(checks, checkDirWriteable("log dir", logDir))
+	checks = append(checks, checkDirWriteable("workdir", workDir))
+	checks = append(checks, checkBackendBinary(backend))
+	for _, pf := range promptFiles {
+		if pf == "" {
+			continue
+		}
+		checks = append(checks, checkPromptFileReadable(pf))
+	}
+
+	printPreflightTable(checks)
+
+	for _, c := range checks {
+		if c.status == preflightFail {
+			return EXNoPerm
+		}
+	}
+	return 0
+}
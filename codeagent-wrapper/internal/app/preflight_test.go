@@ -0,0 +1,116 @@
+package wrapper
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPreflightPaths_WritableDirsSucceed(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "logs")
+	workDir := t.TempDir()
+
+	code, err := preflightPaths(logDir, workDir)
+	if err != nil {
+		t.Fatalf("preflightPaths: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+}
+
+func TestPreflightPaths_PermissionDeniedLogDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("chmod-based permission test is not reliable on Windows")
+	}
+	if os.Getuid() == 0 {
+		t.Skip("chmod-based permission test is not reliable when running as root")
+	}
+
+	blocked := t.TempDir()
+	if err := os.Chmod(blocked, 0o000); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	defer os.Chmod(blocked, 0o755)
+
+	logDir := filepath.Join(blocked, "logs")
+	workDir := t.TempDir()
+
+	code, err := preflightPaths(logDir, workDir)
+	if err == nil {
+		t.Fatal("expected an error for a permission-denied log dir")
+	}
+	if code != EXNoPerm {
+		t.Fatalf("expected exit code %d, got %d", EXNoPerm, code)
+	}
+}
+
+func TestPreflightPaths_PermissionDeniedWorkDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("chmod-based permission test is not reliable on Windows")
+	}
+	if os.Getuid() == 0 {
+		t.Skip("chmod-based permission test is not reliable when running as root")
+	}
+
+	logDir := t.TempDir()
+	workDir := t.TempDir()
+	if err := os.Chmod(workDir, 0o000); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	defer os.Chmod(workDir, 0o755)
+
+	code, err := preflightPaths(logDir, workDir)
+	if err == nil {
+		t.Fatal("expected an error for a permission-denied workdir")
+	}
+	if code != EXNoPerm {
+		t.Fatalf("expected exit code %d, got %d", EXNoPerm, code)
+	}
+}
+
+func TestRunPreflightMode_AllChecksPass(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+	promptPath := filepath.Join(claudeDir, "prompt.md")
+	os.WriteFile(promptPath, []byte("hello"), 0o644)
+
+	logDir := filepath.Join(t.TempDir(), "logs")
+	workDir := t.TempDir()
+
+	code := runPreflightMode(logDir, workDir, "go", []string{promptPath})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+}
+
+func TestRunPreflightMode_MissingBackendBinaryFails(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "logs")
+	workDir := t.TempDir()
+
+	code := runPreflightMode(logDir, workDir, "codeagent-definitely-not-a-real-binary", nil)
+	if code != EXNoPerm {
+		t.Fatalf("expected exit code %d, got %d", EXNoPerm, code)
+	}
+}
+
+func TestRunPreflightMode_UnreadablePromptFileWarnsNotFatal(t *testing.T) {
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	logDir := filepath.Join(t.TempDir(), "logs")
+	workDir := t.TempDir()
+
+	outsideDir := t.TempDir()
+	disallowed := filepath.Join(outsideDir, "disallowed.md")
+	os.WriteFile(disallowed, []byte("nope"), 0o644)
+
+	code := runPreflightMode(logDir, workDir, "go", []string{disallowed})
+	if code != 0 {
+		t.Fatalf("expected a prompt-file warning to not fail the whole preflight, got exit code %d", code)
+	}
+}
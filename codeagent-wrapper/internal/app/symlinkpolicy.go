@@ -0,0 +1,135 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy controls how readAgentPromptFileWithOptions treats
+// symlinks when resolving a prompt file path.
+type SymlinkPolicy int
+
+const (
+	// SymlinkAllowWithinAllowed resolves symlinks and permits them as
+	// long as the resolved target still falls under the allow-list.
+	// This is the historical behavior and remains the default.
+	SymlinkAllowWithinAllowed SymlinkPolicy = iota
+	// SymlinkDeny refuses a path with any symlink component, checked
+	// with os.Lstat without ever resolving one. The check and the later
+	// read are separate calls, so a component swapped for a symlink in
+	// between is not caught.
+	SymlinkDeny
+	// SymlinkFollowAny follows symlinks unconditionally with no target
+	// restriction. Only honored when AllowOutsideClaudeDir is true;
+	// otherwise it is downgraded to SymlinkAllowWithinAllowed.
+	SymlinkFollowAny
+)
+
+func (p SymlinkPolicy) String() string {
+	switch p {
+	case SymlinkDeny:
+		return "deny"
+	case SymlinkFollowAny:
+		return "follow-any"
+	default:
+		return "allow-within-allowed"
+	}
+}
+
+// ParseSymlinkPolicy parses --symlink-policy/CODEAGENT_SYMLINK_POLICY
+// values. An empty string yields the default, SymlinkAllowWithinAllowed.
+func ParseSymlinkPolicy(s string) (SymlinkPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "allow-within-allowed":
+		return SymlinkAllowWithinAllowed, nil
+	case "deny":
+		return SymlinkDeny, nil
+	case "follow-any":
+		return SymlinkFollowAny, nil
+	default:
+		return SymlinkAllowWithinAllowed, fmt.Errorf("unknown symlink policy %q (want deny, allow-within-allowed, or follow-any)", s)
+	}
+}
+
+// ReadAgentPromptFileOptions configures readAgentPromptFileWithOptions.
+type ReadAgentPromptFileOptions struct {
+	AllowOutsideClaudeDir bool
+	SymlinkPolicy         SymlinkPolicy
+}
+
+// symlinkPolicyFromEnv reads CODEAGENT_SYMLINK_POLICY, falling back to
+// SymlinkAllowWithinAllowed for an unset or invalid value (with a
+// warning in the invalid case).
+func symlinkPolicyFromEnv() SymlinkPolicy {
+	raw := os.Getenv("CODEAGENT_SYMLINK_POLICY")
+	if raw == "" {
+		return SymlinkAllowWithinAllowed
+	}
+	policy, err := ParseSymlinkPolicy(raw)
+	if err != nil {
+		logWarn(fmt.Sprintf("CODEAGENT_SYMLINK_POLICY: %v; using default", err))
+		return SymlinkAllowWithinAllowed
+	}
+	return policy
+}
+
+// pathHasSymlinkComponent reports whether any component from path up to
+// the filesystem root is a symlink. Lstat errors on a component (e.g. it
+// doesn't exist) are ignored here and left for the later os.ReadFile
+// call to report.
+func pathHasSymlinkComponent(path string) bool {
+	for {
+		if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+// checkSymlinkPolicy enforces policy against absPath, given allowedDirs
+// for the allow-within-allowed case. It returns a descriptive error if
+// the path is rejected.
+func checkSymlinkPolicy(policy SymlinkPolicy, absPath string, allowedDirs []string) error {
+	switch policy {
+	case SymlinkDeny:
+		if pathHasSymlinkComponent(absPath) {
+			logWarn(fmt.Sprintf("Refusing to read prompt file with a symlink component (symlink-policy=deny): %s", absPath))
+			return fmt.Errorf("prompt file path must not contain a symlink component (symlink-policy=deny)")
+		}
+		return nil
+	case SymlinkFollowAny:
+		return nil
+	default: // SymlinkAllowWithinAllowed
+		resolvedPath, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			// No symlink to resolve (or target doesn't exist yet);
+			// defer to the later os.ReadFile call for the real error.
+			return nil
+		}
+		resolvedPath = filepath.Clean(resolvedPath)
+		resolvedAllowed := make([]string, 0, len(allowedDirs))
+		for _, dir := range allowedDirs {
+			resolvedBase, err := filepath.EvalSymlinks(dir)
+			if err != nil {
+				continue
+			}
+			resolvedAllowed = append(resolvedAllowed, filepath.Clean(resolvedBase))
+		}
+		if len(resolvedAllowed) == 0 {
+			return nil
+		}
+		for _, dir := range resolvedAllowed {
+			if isWithinDir(resolvedPath, dir) {
+				return nil
+			}
+		}
+		logWarn(fmt.Sprintf("Refusing to read prompt file outside allowed dirs (%s) (resolved): %s", strings.Join(resolvedAllowed, ", "), resolvedPath))
+		return fmt.Errorf("prompt file must be under ~/.claude, ~/.codex, or ~/.codeagent/agents")
+	}
+}
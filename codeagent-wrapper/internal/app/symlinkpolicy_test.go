@@ -0,0 +1,157 @@
+package wrapper
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	cases := map[string]SymlinkPolicy{
+		"":                     SymlinkAllowWithinAllowed,
+		"allow-within-allowed": SymlinkAllowWithinAllowed,
+		"DENY":                 SymlinkDeny,
+		"follow-any":           SymlinkFollowAny,
+	}
+	for input, want := range cases {
+		got, err := ParseSymlinkPolicy(input)
+		if err != nil {
+			t.Fatalf("ParseSymlinkPolicy(%q): %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ParseSymlinkPolicy(%q) = %v, want %v", input, got, want)
+		}
+	}
+	if _, err := ParseSymlinkPolicy("bogus"); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
+
+func TestReadAgentPromptFile_HonorsSymlinkPolicyEnvVar(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not reliably creatable on Windows without elevation")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+
+	outside := t.TempDir()
+	secretPath := filepath.Join(outside, "secret.md")
+	if err := os.WriteFile(secretPath, []byte("secret contents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	linkPath := filepath.Join(claudeDir, "escape.md")
+	if err := os.Symlink(secretPath, linkPath); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	t.Setenv("CODEAGENT_SYMLINK_POLICY", "deny")
+	if _, err := readAgentPromptFile(linkPath, false); err == nil {
+		t.Error("CODEAGENT_SYMLINK_POLICY=deny: expected a symlink component to be rejected")
+	}
+
+	t.Setenv("CODEAGENT_SYMLINK_POLICY", "")
+	if _, err := readAgentPromptFile(linkPath, false); err == nil {
+		t.Error("default policy: expected a symlink escaping the allow-list to still be rejected")
+	}
+}
+
+func TestReadAgentPromptFileWithOptions_SymlinkInsideAllowedPointingOutside(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not reliably creatable on Windows without elevation")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+
+	outside := t.TempDir()
+	secretPath := filepath.Join(outside, "secret.md")
+	if err := os.WriteFile(secretPath, []byte("secret contents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	linkPath := filepath.Join(claudeDir, "escape.md")
+	if err := os.Symlink(secretPath, linkPath); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := readAgentPromptFileWithOptions(linkPath, ReadAgentPromptFileOptions{
+		SymlinkPolicy: SymlinkAllowWithinAllowed,
+	}); err == nil {
+		t.Error("allow-within-allowed: expected a symlink escaping the allow-list to be rejected")
+	}
+
+	if _, err := readAgentPromptFileWithOptions(linkPath, ReadAgentPromptFileOptions{
+		SymlinkPolicy: SymlinkDeny,
+	}); err == nil {
+		t.Error("deny: expected any symlink component to be rejected")
+	}
+
+	content, err := readAgentPromptFileWithOptions(linkPath, ReadAgentPromptFileOptions{
+		AllowOutsideClaudeDir: true,
+		SymlinkPolicy:         SymlinkFollowAny,
+	})
+	if err != nil {
+		t.Fatalf("follow-any: expected the symlink to be followed, got error: %v", err)
+	}
+	if content != "secret contents" {
+		t.Errorf("follow-any: got %q, want %q", content, "secret contents")
+	}
+}
+
+func TestReadAgentPromptFileWithOptions_SymlinkComponentInMiddleOfPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not reliably creatable on Windows without elevation")
+	}
+
+	home := t.TempDir()
+	setAgentsTestHome(t, home)
+
+	claudeDir := filepath.Join(home, ".claude")
+	os.MkdirAll(claudeDir, 0o755)
+
+	realDir := filepath.Join(home, "real-agents")
+	os.MkdirAll(realDir, 0o755)
+	promptPath := filepath.Join(realDir, "prompt.md")
+	if err := os.WriteFile(promptPath, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	linkDir := filepath.Join(claudeDir, "link")
+	if err := os.Symlink(realDir, linkDir); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+	pathThroughLink := filepath.Join(linkDir, "prompt.md")
+
+	if _, err := readAgentPromptFileWithOptions(pathThroughLink, ReadAgentPromptFileOptions{
+		SymlinkPolicy: SymlinkDeny,
+	}); err == nil {
+		t.Error("deny: expected a symlink directory component to be rejected")
+	}
+
+	// The link resolves to realDir, which is outside the allow-list, so
+	// allow-within-allowed rejects it the same way it would a direct
+	// symlink escape.
+	if _, err := readAgentPromptFileWithOptions(pathThroughLink, ReadAgentPromptFileOptions{
+		SymlinkPolicy: SymlinkAllowWithinAllowed,
+	}); err == nil {
+		t.Error("allow-within-allowed: expected the resolved target outside ~/.claude to be rejected")
+	}
+
+	content, err := readAgentPromptFileWithOptions(pathThroughLink, ReadAgentPromptFileOptions{
+		AllowOutsideClaudeDir: true,
+		SymlinkPolicy:         SymlinkFollowAny,
+	})
+	if err != nil {
+		t.Fatalf("follow-any: expected the link to be followed, got: %v", err)
+	}
+	if content != "hello" {
+		t.Errorf("got %q, want %q", content, "hello")
+	}
+}
@@ -1,10 +1,13 @@
 package executor
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -131,57 +134,216 @@ func WrapTaskWithAgentPrompt(prompt string, task string) string {
 	return "<agent-prompt>\n" + prompt + "\n</agent-prompt>\n\n" + task
 }
 
-// techSkillMap maps file-existence fingerprints to skill names.
-var techSkillMap = []struct {
-	Files  []string // any of these files â†’ this tech
-	Skills []string
-}{
-	{Files: []string{"go.mod", "go.sum"}, Skills: []string{"golang-base-practices"}},
+// skillMatch is a candidate skill surfaced by a techSkillEntry, ranked by
+// Priority so a stronger content-based fingerprint (e.g. "react" in
+// package.json) outranks a weaker existence-only one.
+type skillMatch struct {
+	Skill    string
+	Priority int
+}
+
+// techSkillEntry maps a file-existence fingerprint to skill names. When
+// Matcher is set, it inspects the content of whichever Files entry was
+// found (not just its presence) to pick a more specific set of skills;
+// Skills is the existence-only fallback used when Matcher is nil or
+// returns no matches.
+type techSkillEntry struct {
+	Files   []string // any of these files → this tech
+	Skills  []string
+	Matcher func(filename string, data []byte) []skillMatch
+}
+
+var techSkillMap = []techSkillEntry{
+	{Files: []string{"go.mod", "go.sum"}, Skills: []string{"golang-base-practices"}, Matcher: matchGoMod},
 	{Files: []string{"Cargo.toml"}, Skills: []string{"rust-best-practices"}},
-	{Files: []string{"pyproject.toml", "setup.py", "requirements.txt", "Pipfile"}, Skills: []string{"python-best-practices"}},
-	{Files: []string{"package.json"}, Skills: []string{"vercel-react-best-practices", "frontend-design"}},
+	{Files: []string{"pyproject.toml", "setup.py", "requirements.txt", "Pipfile"}, Skills: []string{"python-best-practices"}, Matcher: matchPyProject},
+	{Files: []string{"package.json"}, Skills: []string{"vercel-react-best-practices", "frontend-design"}, Matcher: matchPackageJSON},
 	{Files: []string{"vue.config.js", "vite.config.ts", "nuxt.config.ts"}, Skills: []string{"vue-web-app"}},
 }
 
-func findSkillFile(home, skill string) string {
-	roots := []string{
-		filepath.Join(home, ".codex", "skills"),
-		filepath.Join(home, ".claude", "skills"),
+// packageJSON is the subset of package.json fields used to fingerprint
+// the frontend framework in use.
+type packageJSON struct {
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// matchPackageJSON picks a framework-specific skill from package.json's
+// dependencies/devDependencies, alongside the generic frontend-design
+// skill that applies to any Node project.
+func matchPackageJSON(filename string, data []byte) []skillMatch {
+	matches := []skillMatch{{Skill: "frontend-design", Priority: 0}}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return matches
 	}
-	for _, root := range roots {
-		path := filepath.Join(root, skill, "SKILL.md")
-		if _, err := os.Stat(path); err == nil {
-			return path
+	has := func(names ...string) bool {
+		for _, n := range names {
+			if _, ok := pkg.Dependencies[n]; ok {
+				return true
+			}
+			if _, ok := pkg.DevDependencies[n]; ok {
+				return true
+			}
 		}
+		return false
+	}
+
+	if has("react", "next") {
+		matches = append(matches, skillMatch{Skill: "vercel-react-best-practices", Priority: 10})
+	}
+	if has("vue", "nuxt") {
+		matches = append(matches, skillMatch{Skill: "vue-web-app", Priority: 10})
+	}
+	if has("svelte") {
+		matches = append(matches, skillMatch{Skill: "svelte-best-practices", Priority: 10})
+	}
+	return matches
+}
+
+// goModFrameworks maps an import path substring found in go.mod to the
+// framework-specific skill it fingerprints.
+var goModFrameworks = []struct {
+	marker string
+	skill  string
+}{
+	{"gin-gonic/gin", "gin-best-practices"},
+	{"labstack/echo", "echo-best-practices"},
+	{"spf13/cobra", "cobra-cli-best-practices"},
+	{"charmbracelet/bubbletea", "bubbletea-tui-best-practices"},
+}
+
+// matchGoMod picks framework-specific skills from go.mod's require block;
+// go.sum carries no module names worth parsing, so it falls through to
+// the generic golang-base-practices skill.
+func matchGoMod(filename string, data []byte) []skillMatch {
+	matches := []skillMatch{{Skill: "golang-base-practices", Priority: 0}}
+	if filename != "go.mod" {
+		return matches
+	}
+	content := string(data)
+	for _, fw := range goModFrameworks {
+		if strings.Contains(content, fw.marker) {
+			matches = append(matches, skillMatch{Skill: fw.skill, Priority: 10})
+		}
+	}
+	return matches
+}
+
+// pyProjectFrameworks maps a lowercased pyproject.toml substring to the
+// framework-specific skill it fingerprints.
+var pyProjectFrameworks = []struct {
+	marker string
+	skill  string
+}{
+	{"django", "django-best-practices"},
+	{"fastapi", "fastapi-best-practices"},
+	{"flask", "flask-best-practices"},
+}
+
+// matchPyProject picks a framework-specific skill from pyproject.toml's
+// dependency list; setup.py/requirements.txt/Pipfile fall through to the
+// generic python-best-practices skill since they're not parsed here.
+func matchPyProject(filename string, data []byte) []skillMatch {
+	matches := []skillMatch{{Skill: "python-best-practices", Priority: 0}}
+	if filename != "pyproject.toml" {
+		return matches
+	}
+	content := strings.ToLower(string(data))
+	for _, fw := range pyProjectFrameworks {
+		if strings.Contains(content, fw.marker) {
+			matches = append(matches, skillMatch{Skill: fw.skill, Priority: 10})
+		}
+	}
+	return matches
+}
+
+// findSkillFile returns the skill-relative path to skill's SKILL.md
+// within skillsFS (e.g. "golang-base-practices/SKILL.md"), or "" if no
+// layer has it. A remote ref (host/path@rev) is looked up only at its
+// sync-cache path (".cache/<host>/<path>@<rev>/SKILL.md") in skillsFS's
+// layers; it does not fall back to a local skill directory, and
+// findSkillFile never fetches — an uncached remote ref just isn't found.
+func findSkillFile(skillsFS SkillFS, skill string) string {
+	if ref, err := ParseRemoteSkillRef(skill); err == nil {
+		if _, err := skillsFS.Stat(ref.CachePath()); err == nil {
+			return ref.CachePath()
+		}
+		return ""
+	}
+	path := filepath.Join(skill, "SKILL.md")
+	if _, err := skillsFS.Stat(path); err == nil {
+		return path
 	}
 	return ""
 }
 
 // DetectProjectSkills scans workDir for tech-stack fingerprints and returns
 // skill names that are both detected and installed (prefers ~/.codex/skills,
-// falls back to ~/.claude/skills).
+// falls back to ~/.claude/skills, then ExtraSkillRoots).
 func DetectProjectSkills(workDir string) []string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil
 	}
-	var detected []string
-	seen := make(map[string]bool)
+	return DetectProjectSkillsFS(NewBasePathSkillFS(osSkillFS{}, workDir), NewDefaultSkillFS(home))
+}
+
+// DetectProjectSkillsFS is the SkillFS-driven core of DetectProjectSkills:
+// workFS is rooted at the project directory being fingerprinted, skillsFS
+// is the layered set of installed skill roots. The result is stably
+// ordered by strongest fingerprint first (content-based matches outrank
+// existence-only ones), then by fingerprint declaration order.
+func DetectProjectSkillsFS(workFS SkillFS, skillsFS SkillFS) []string {
+	type candidate struct {
+		skillMatch
+		order int
+	}
+	var candidates []candidate
+	order := 0
 	for _, entry := range techSkillMap {
 		for _, f := range entry.Files {
-			if _, err := os.Stat(filepath.Join(workDir, f)); err == nil {
-				for _, skill := range entry.Skills {
-					if seen[skill] {
-						continue
-					}
-					if findSkillFile(home, skill) != "" {
-						detected = append(detected, skill)
-						seen[skill] = true
-					}
+			if _, err := workFS.Stat(f); err != nil {
+				continue
+			}
+			matches := []skillMatch(nil)
+			if entry.Matcher != nil {
+				if data, err := workFS.ReadFile(f); err == nil {
+					matches = entry.Matcher(f, data)
+				}
+			}
+			if len(matches) == 0 {
+				for _, s := range entry.Skills {
+					matches = append(matches, skillMatch{Skill: s, Priority: 0})
 				}
-				break // one matching file is enough for this entry
 			}
+			for _, m := range matches {
+				candidates = append(candidates, candidate{skillMatch: m, order: order})
+				order++
+			}
+			break // one matching file is enough for this entry
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
 		}
+		return candidates[i].order < candidates[j].order
+	})
+
+	var detected []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if seen[c.Skill] {
+			continue
+		}
+		if findSkillFile(skillsFS, c.Skill) == "" {
+			continue
+		}
+		detected = append(detected, c.Skill)
+		seen[c.Skill] = true
 	}
 	return detected
 }
@@ -199,65 +361,261 @@ func ResolveSkillContent(skills []string, maxBudget int) string {
 	if err != nil {
 		return ""
 	}
+	return ResolveSkillContentFS(NewDefaultSkillFS(home), skills, maxBudget)
+}
+
+// ResolveSkillContentFS is the SkillFS-driven core of ResolveSkillContent.
+func ResolveSkillContentFS(skillsFS SkillFS, skills []string, maxBudget int) string {
+	return ResolveSkillsFS(skillsFS, skills, maxBudget).Content
+}
+
+// SkillMeta is the frontmatter-derived metadata for one resolved skill,
+// surfaced so the caller wrapping the task prompt can enforce or forward
+// it to the underlying agent (e.g. restrict tools, pin a model).
+type SkillMeta struct {
+	Name         string
+	Description  string
+	AllowedTools []string
+	Model        string
+}
+
+// ResolvedSkills is the result of resolving a list of skill names to
+// their SKILL.md content (joined, ready for prompt injection) plus
+// per-skill frontmatter metadata.
+type ResolvedSkills struct {
+	Content string
+	Meta    []SkillMeta
+}
+
+// escapeAttr escapes characters that would otherwise break out of a
+// double-quoted XML/HTML-style attribute value.
+func escapeAttr(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "\"", "&quot;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	return s
+}
+
+// ResolveSkillsFS reads SKILL.md files for the given skill names,
+// transitively expanding any `requires` frontmatter (with cycle
+// detection), wraps each in <skill> tags, and enforces a character
+// budget to prevent context bloat. A skill's own `budget` frontmatter
+// field caps its share of maxBudget; `description` is surfaced as a
+// `<skill description="...">` attribute so downstream models get a
+// one-line summary even when the body is truncated.
+func ResolveSkillsFS(skillsFS SkillFS, skills []string, maxBudget int) ResolvedSkills {
 	if maxBudget <= 0 {
 		maxBudget = defaultSkillBudget
 	}
-	var sections []string
-	remaining := maxBudget
-	for _, name := range skills {
-		name = strings.TrimSpace(name)
-		if name == "" {
-			continue
+
+	visiting := make(map[string]bool)
+	visited := make(map[string]bool)
+	var order []string
+
+	var visit func(name string, chain []string)
+	visit = func(name string, chain []string) {
+		if visited[name] {
+			return
 		}
-		if !validSkillName.MatchString(name) {
+		if visiting[name] {
+			logWarn(fmt.Sprintf("skill %q: cyclic requires (%s), skipping", name, strings.Join(append(append([]string{}, chain...), name), " -> ")))
+			return
+		}
+		_, refErr := ParseRemoteSkillRef(name)
+		isRemoteRef := refErr == nil
+		if !isRemoteRef && !validSkillName.MatchString(name) {
 			logWarn(fmt.Sprintf("skill %q: invalid name (must contain only [a-zA-Z0-9_-]), skipping", name))
-			continue
+			return
 		}
-		path := findSkillFile(home, name)
+		path := findSkillFile(skillsFS, name)
 		if path == "" {
-			logWarn(fmt.Sprintf("skill %q: SKILL.md not found or empty, skipping", name))
-			continue
+			if isRemoteRef {
+				logWarn(fmt.Sprintf("skill %q: not cached, skipping (run `codeagent skills sync --allow-network` to populate the cache)", name))
+			} else {
+				logWarn(fmt.Sprintf("skill %q: SKILL.md not found or empty, skipping", name))
+			}
+			return
 		}
-		data, err := os.ReadFile(path)
+		data, err := skillsFS.ReadFile(path)
 		if err != nil || len(data) == 0 {
 			logWarn(fmt.Sprintf("skill %q: SKILL.md not found or empty, skipping", name))
+			return
+		}
+		fm, _ := parseSkillFrontmatter(strings.TrimSpace(string(data)))
+
+		visiting[name] = true
+		for _, req := range fm.Requires {
+			req = strings.TrimSpace(req)
+			if req == "" || req == name {
+				continue
+			}
+			visit(req, append(chain, name))
+		}
+		visiting[name] = false
+
+		visited[name] = true
+		order = append(order, name)
+	}
+
+	for _, name := range skills {
+		name = strings.TrimSpace(name)
+		if name == "" {
 			continue
 		}
-		body := stripYAMLFrontmatter(strings.TrimSpace(string(data)))
-		tagOverhead := len("<skill name=\"\">") + len(name) + len("\n") + len("\n</skill>")
-		bodyBudget := remaining - tagOverhead
-		if bodyBudget <= 0 {
-			logWarn(fmt.Sprintf("skill %q: skipped, insufficient budget for tags", name))
+		visit(name, nil)
+	}
+
+	var sections []string
+	var metas []SkillMeta
+	remaining := maxBudget
+	for _, name := range order {
+		path := findSkillFile(skillsFS, name)
+		data, err := skillsFS.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		fm, body := parseSkillFrontmatter(strings.TrimSpace(string(data)))
+
+		tag := "<skill name=\"" + name + "\""
+		if fm.Description != "" {
+			tag += " description=\"" + escapeAttr(fm.Description) + "\""
+		}
+		tag += ">"
+		tagOverhead := len(tag) + len("\n") + len("\n</skill>")
+
+		if remaining-tagOverhead <= 0 {
+			logWarn(fmt.Sprintf("skill %q: skipped, insufficient remaining budget for tags", name))
 			break
 		}
+
+		bodyBudget := remaining
+		if fm.Budget > 0 && fm.Budget < bodyBudget {
+			bodyBudget = fm.Budget
+		}
+		bodyBudget -= tagOverhead
+		if bodyBudget <= 0 {
+			logWarn(fmt.Sprintf("skill %q: skipped, per-skill budget too small for tags", name))
+			continue
+		}
 		if len(body) > bodyBudget {
 			logWarn(fmt.Sprintf("skill %q: truncated from %d to %d chars (budget)", name, len(body), bodyBudget))
 			body = body[:bodyBudget]
 		}
 		remaining -= len(body) + tagOverhead
-		sections = append(sections, "<skill name=\""+name+"\">\n"+body+"\n</skill>")
+
+		sections = append(sections, tag+"\n"+body+"\n</skill>")
+		metas = append(metas, SkillMeta{
+			Name:         name,
+			Description:  fm.Description,
+			AllowedTools: fm.AllowedTools,
+			Model:        fm.Model,
+		})
 		if remaining <= 0 {
 			break
 		}
 	}
-	if len(sections) == 0 {
-		return ""
-	}
-	return strings.Join(sections, "\n\n")
+
+	return ResolvedSkills{Content: strings.Join(sections, "\n\n"), Meta: metas}
 }
 
-func stripYAMLFrontmatter(s string) string {
+// skillFrontmatter holds the YAML frontmatter fields a SKILL.md may
+// declare. Only fields the wrapper acts on are parsed; anything else in
+// the block is ignored.
+type skillFrontmatter struct {
+	Name         string
+	Description  string
+	AllowedTools []string
+	Model        string
+	Budget       int
+	Requires     []string
+}
+
+// parseSkillFrontmatter extracts name/description/allowed-tools/model/
+// budget/requires from a SKILL.md's leading "---" YAML block and returns
+// them alongside the remaining body. It's a minimal line-based parser
+// (not a full YAML implementation) scoped to the handful of scalar and
+// flow-sequence fields skills actually declare.
+func parseSkillFrontmatter(s string) (skillFrontmatter, string) {
+	var fm skillFrontmatter
 	s = strings.ReplaceAll(s, "\r\n", "\n")
 	if !strings.HasPrefix(s, "---") {
-		return s
+		return fm, s
 	}
 	idx := strings.Index(s[3:], "\n---")
 	if idx < 0 {
-		return s
+		return fm, s
+	}
+	block := s[3 : 3+idx]
+	body := s[3+idx+4:]
+	if len(body) > 0 && body[0] == '\n' {
+		body = body[1:]
+	}
+	body = strings.TrimSpace(body)
+
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "name":
+			fm.Name = unquoteYAMLScalar(value)
+		case "description":
+			fm.Description = unquoteYAMLScalar(value)
+		case "model":
+			fm.Model = unquoteYAMLScalar(value)
+		case "budget":
+			if n, err := strconv.Atoi(value); err == nil {
+				fm.Budget = n
+			}
+		case "allowed-tools":
+			fm.AllowedTools = parseYAMLFlowList(value)
+		case "requires":
+			fm.Requires = parseYAMLFlowList(value)
+		}
 	}
-	result := s[3+idx+4:]
-	if len(result) > 0 && result[0] == '\n' {
-		result = result[1:]
+	return fm, body
+}
+
+func unquoteYAMLScalar(v string) string {
+	if len(v) >= 2 {
+		if (v[0] == '"' && v[len(v)-1] == '"') || (v[0] == '\'' && v[len(v)-1] == '\'') {
+			return v[1 : len(v)-1]
+		}
 	}
-	return strings.TrimSpace(result)
+	return v
+}
+
+// parseYAMLFlowList parses a YAML flow sequence like ["Bash", "Read"] or
+// a bare comma-separated fallback like "Bash, Read".
+func parseYAMLFlowList(v string) []string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(v, "[")
+	v = strings.TrimSuffix(v, "]")
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = unquoteYAMLScalar(strings.TrimSpace(p))
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// stripYAMLFrontmatter strips a SKILL.md's leading YAML frontmatter
+// block, returning only the body.
+func stripYAMLFrontmatter(s string) string {
+	_, body := parseSkillFrontmatter(s)
+	return body
 }
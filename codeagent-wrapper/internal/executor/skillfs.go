@@ -0,0 +1,224 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SkillFS abstracts the filesystem operations needed to discover and read
+// skills, so DetectProjectSkills, findSkillFile, and ResolveSkillContent
+// can run against an in-memory tree in tests or a composite of several
+// roots in production instead of touching the real filesystem directly.
+type SkillFS interface {
+	Stat(name string) (os.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	Walk(root string, fn filepath.WalkFunc) error
+}
+
+// osSkillFS is the default SkillFS, backed by the real filesystem.
+type osSkillFS struct{}
+
+func (osSkillFS) Stat(name string) (os.FileInfo, error) { return os.Stat(name) }
+func (osSkillFS) ReadFile(name string) ([]byte, error)  { return os.ReadFile(name) }
+func (osSkillFS) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+// basePathSkillFS scopes a SkillFS so every path is resolved relative to
+// base, mirroring afero's BasePathFs. It lets a single root directory
+// (e.g. ~/.codex/skills, a repo-local .skills/ dir) be addressed with
+// skill-relative paths like "golang-base-practices/SKILL.md".
+type basePathSkillFS struct {
+	base string
+	fs   SkillFS
+}
+
+// NewBasePathSkillFS returns a SkillFS that resolves every path relative
+// to base before delegating to fs.
+func NewBasePathSkillFS(fs SkillFS, base string) SkillFS {
+	return &basePathSkillFS{base: base, fs: fs}
+}
+
+func (b *basePathSkillFS) resolve(name string) string { return filepath.Join(b.base, name) }
+
+func (b *basePathSkillFS) Stat(name string) (os.FileInfo, error) {
+	return b.fs.Stat(b.resolve(name))
+}
+
+func (b *basePathSkillFS) ReadFile(name string) ([]byte, error) {
+	return b.fs.ReadFile(b.resolve(name))
+}
+
+func (b *basePathSkillFS) Walk(root string, fn filepath.WalkFunc) error {
+	base := b.base
+	return b.fs.Walk(b.resolve(root), func(path string, info os.FileInfo, err error) error {
+		rel, relErr := filepath.Rel(base, path)
+		if relErr != nil {
+			rel = path
+		}
+		return fn(rel, info, err)
+	})
+}
+
+// CompositeSkillFS layers several SkillFS roots with precedence: the
+// first layer holding a given path wins Stat/ReadFile, and Walk reports
+// the union of all layers with earlier layers shadowing later ones for
+// duplicate paths. This mirrors the afero copy-on-write compositing
+// pattern and is how DetectProjectSkills/ResolveSkillContent let callers
+// register extra skill roots (e.g. a repo-local .skills/ dir, an
+// org-wide shared mount) alongside ~/.codex/skills and ~/.claude/skills.
+type CompositeSkillFS struct {
+	layers []SkillFS
+}
+
+// NewCompositeSkillFS returns a SkillFS that searches layers in order.
+func NewCompositeSkillFS(layers ...SkillFS) *CompositeSkillFS {
+	return &CompositeSkillFS{layers: layers}
+}
+
+func (c *CompositeSkillFS) Stat(name string) (os.FileInfo, error) {
+	var lastErr error = &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	for _, layer := range c.layers {
+		if info, err := layer.Stat(name); err == nil {
+			return info, nil
+		} else {
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *CompositeSkillFS) ReadFile(name string) ([]byte, error) {
+	var lastErr error = &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	for _, layer := range c.layers {
+		if data, err := layer.ReadFile(name); err == nil {
+			return data, nil
+		} else {
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *CompositeSkillFS) Walk(root string, fn filepath.WalkFunc) error {
+	seen := make(map[string]bool)
+	for _, layer := range c.layers {
+		err := layer.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			return fn(path, info, nil)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemSkillFS is an in-memory SkillFS for hermetic tests. Files are keyed
+// by their cleaned, slash-normalized path; directories are implied by
+// file paths and need no separate entry.
+type MemSkillFS struct {
+	files map[string][]byte
+}
+
+// NewMemSkillFS returns an empty in-memory SkillFS.
+func NewMemSkillFS() *MemSkillFS {
+	return &MemSkillFS{files: make(map[string][]byte)}
+}
+
+func (m *MemSkillFS) normalize(name string) string {
+	return filepath.ToSlash(filepath.Clean(name))
+}
+
+// WriteFile adds or overwrites a file in the in-memory tree.
+func (m *MemSkillFS) WriteFile(name string, data []byte) {
+	m.files[m.normalize(name)] = append([]byte(nil), data...)
+}
+
+func (m *MemSkillFS) Stat(name string) (os.FileInfo, error) {
+	key := m.normalize(name)
+	if data, ok := m.files[key]; ok {
+		return memFileInfo{name: filepath.Base(key), size: int64(len(data))}, nil
+	}
+	prefix := key + "/"
+	for k := range m.files {
+		if strings.HasPrefix(k, prefix) {
+			return memFileInfo{name: filepath.Base(key), isDir: true}, nil
+		}
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+func (m *MemSkillFS) ReadFile(name string) ([]byte, error) {
+	key := m.normalize(name)
+	data, ok := m.files[key]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *MemSkillFS) Walk(root string, fn filepath.WalkFunc) error {
+	root = m.normalize(root)
+	keys := make([]string, 0, len(m.files))
+	for k := range m.files {
+		if k == root || strings.HasPrefix(k, root+"/") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn(k, memFileInfo{name: filepath.Base(k), size: int64(len(m.files[k]))}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memFileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (i memFileInfo) Name() string { return i.name }
+func (i memFileInfo) Size() int64  { return i.size }
+func (i memFileInfo) Mode() os.FileMode {
+	if i.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return i.isDir }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+// ExtraSkillRoots lets callers register additional skill directories
+// (e.g. a repo-local .skills/ dir or an org-wide shared mount) beyond
+// the built-in ~/.codex/skills and ~/.claude/skills. Roots are searched
+// in registration order, after the built-ins, so the first definition
+// of a given skill name wins.
+var ExtraSkillRoots []string
+
+// NewDefaultSkillFS builds the layered SkillFS used when no explicit FS
+// is supplied: ~/.codex/skills, then ~/.claude/skills, then any roots
+// registered in ExtraSkillRoots, in that precedence order. Because the
+// `codeagent skills sync` cache lives under ~/.codex/skills/.cache, it's
+// already reachable through the first layer and is consulted before
+// ~/.claude/skills or any extra root.
+func NewDefaultSkillFS(home string) SkillFS {
+	layers := []SkillFS{
+		NewBasePathSkillFS(osSkillFS{}, filepath.Join(home, ".codex", "skills")),
+		NewBasePathSkillFS(osSkillFS{}, filepath.Join(home, ".claude", "skills")),
+	}
+	for _, root := range ExtraSkillRoots {
+		layers = append(layers, NewBasePathSkillFS(osSkillFS{}, root))
+	}
+	return NewCompositeSkillFS(layers...)
+}
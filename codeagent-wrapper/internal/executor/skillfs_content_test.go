@@ -0,0 +1,98 @@
+package executor
+
+import "testing"
+
+func installAll(skillsFS *MemSkillFS, names ...string) {
+	for _, n := range names {
+		skillsFS.WriteFile(n+"/SKILL.md", []byte("# "+n))
+	}
+}
+
+func TestDetectProjectSkillsFS_ReactOnly(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("package.json", []byte(`{"dependencies":{"react":"18.0.0"}}`))
+
+	skillsFS := NewMemSkillFS()
+	installAll(skillsFS, "vercel-react-best-practices", "vue-web-app", "svelte-best-practices", "frontend-design")
+
+	skills := DetectProjectSkillsFS(workFS, skillsFS)
+	want := []string{"vercel-react-best-practices", "frontend-design"}
+	if len(skills) != len(want) {
+		t.Fatalf("got %v, want %v", skills, want)
+	}
+	for i := range want {
+		if skills[i] != want[i] {
+			t.Fatalf("got %v, want %v", skills, want)
+		}
+	}
+}
+
+func TestDetectProjectSkillsFS_VueOnly(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("package.json", []byte(`{"devDependencies":{"nuxt":"3.0.0"}}`))
+
+	skillsFS := NewMemSkillFS()
+	installAll(skillsFS, "vercel-react-best-practices", "vue-web-app", "svelte-best-practices", "frontend-design")
+
+	skills := DetectProjectSkillsFS(workFS, skillsFS)
+	for _, s := range skills {
+		if s == "vercel-react-best-practices" || s == "svelte-best-practices" {
+			t.Fatalf("expected disjoint set, found %q in %v", s, skills)
+		}
+	}
+	found := false
+	for _, s := range skills {
+		if s == "vue-web-app" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected vue-web-app in %v", skills)
+	}
+}
+
+func TestDetectProjectSkillsFS_MixedWorkspace(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("go.mod", []byte("module test\n\nrequire github.com/gin-gonic/gin v1.9.0\n"))
+	workFS.WriteFile("package.json", []byte(`{"dependencies":{"react":"18.0.0"}}`))
+
+	skillsFS := NewMemSkillFS()
+	installAll(skillsFS, "golang-base-practices", "gin-best-practices", "vercel-react-best-practices", "frontend-design", "vue-web-app")
+
+	skills := DetectProjectSkillsFS(workFS, skillsFS)
+	want := map[string]bool{"golang-base-practices": true, "gin-best-practices": true, "vercel-react-best-practices": true, "frontend-design": true}
+	if len(skills) != len(want) {
+		t.Fatalf("got %v, want keys of %v", skills, want)
+	}
+	for _, s := range skills {
+		if !want[s] {
+			t.Fatalf("unexpected skill %q in %v", s, skills)
+		}
+	}
+}
+
+func TestDetectProjectSkillsFS_GoFrameworkPriority(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("go.mod", []byte("module test\n\nrequire github.com/spf13/cobra v1.8.0\n"))
+
+	skillsFS := NewMemSkillFS()
+	installAll(skillsFS, "golang-base-practices", "cobra-cli-best-practices")
+
+	skills := DetectProjectSkillsFS(workFS, skillsFS)
+	if len(skills) != 2 || skills[0] != "cobra-cli-best-practices" || skills[1] != "golang-base-practices" {
+		t.Fatalf("expected framework-specific skill first, got %v", skills)
+	}
+}
+
+func TestDetectProjectSkillsFS_PyProjectFramework(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("pyproject.toml", []byte("[tool.poetry.dependencies]\nfastapi = \"^0.100\"\n"))
+
+	skillsFS := NewMemSkillFS()
+	installAll(skillsFS, "python-best-practices", "fastapi-best-practices")
+
+	skills := DetectProjectSkillsFS(workFS, skillsFS)
+	if len(skills) != 2 || skills[0] != "fastapi-best-practices" {
+		t.Fatalf("expected fastapi-best-practices first, got %v", skills)
+	}
+}
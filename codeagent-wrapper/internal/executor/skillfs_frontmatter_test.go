@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSkillFrontmatter_Fields(t *testing.T) {
+	input := "---\n" +
+		"name: do-thing\n" +
+		"description: Does the thing\n" +
+		"model: claude-sonnet\n" +
+		"budget: 500\n" +
+		"allowed-tools: [\"Bash\", \"Read\"]\n" +
+		"requires: [other-skill, \"another-skill\"]\n" +
+		"---\n\n# Body\nContent."
+
+	fm, body := parseSkillFrontmatter(input)
+	if fm.Name != "do-thing" || fm.Description != "Does the thing" || fm.Model != "claude-sonnet" {
+		t.Fatalf("unexpected scalar fields: %+v", fm)
+	}
+	if fm.Budget != 500 {
+		t.Fatalf("expected budget 500, got %d", fm.Budget)
+	}
+	if len(fm.AllowedTools) != 2 || fm.AllowedTools[0] != "Bash" || fm.AllowedTools[1] != "Read" {
+		t.Fatalf("unexpected allowed-tools: %v", fm.AllowedTools)
+	}
+	if len(fm.Requires) != 2 || fm.Requires[0] != "other-skill" || fm.Requires[1] != "another-skill" {
+		t.Fatalf("unexpected requires: %v", fm.Requires)
+	}
+	if body != "# Body\nContent." {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+func TestResolveSkillsFS_RequiresExpandsDependencyFirst(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("base/SKILL.md", []byte("---\nname: base\n---\n\n# Base"))
+	skillsFS.WriteFile("child/SKILL.md", []byte("---\nname: child\nrequires: [base]\n---\n\n# Child"))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{"child"}, 0)
+	baseIdx := strings.Index(resolved.Content, `<skill name="base">`)
+	childIdx := strings.Index(resolved.Content, `<skill name="child">`)
+	if baseIdx < 0 || childIdx < 0 || baseIdx > childIdx {
+		t.Fatalf("expected base before child, got %q", resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_CyclicRequiresSkipped(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("a/SKILL.md", []byte("---\nname: a\nrequires: [b]\n---\n\n# A"))
+	skillsFS.WriteFile("b/SKILL.md", []byte("---\nname: b\nrequires: [a]\n---\n\n# B"))
+
+	// The cycle must not cause infinite recursion, and each skill should
+	// still resolve exactly once despite requiring each other.
+	resolved := ResolveSkillsFS(skillsFS, []string{"a"}, 0)
+	if strings.Index(resolved.Content, `<skill name="a">`) < 0 {
+		t.Fatalf("expected the originally requested skill to still resolve, got %q", resolved.Content)
+	}
+	if strings.Count(resolved.Content, `<skill name="a">`) != 1 {
+		t.Fatalf("expected skill to appear exactly once despite cycle, got %q", resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_MissingRequiresSkipsOnlyDependency(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("child/SKILL.md", []byte("---\nname: child\nrequires: [missing]\n---\n\n# Child"))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{"child"}, 0)
+	if strings.Index(resolved.Content, `<skill name="child">`) < 0 {
+		t.Fatalf("expected child to resolve despite missing dependency, got %q", resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_PerSkillBudgetOverride(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	longBody := make([]byte, 0, 500)
+	for i := 0; i < 500; i++ {
+		longBody = append(longBody, 'x')
+	}
+	skillsFS.WriteFile("capped/SKILL.md", append([]byte("---\nname: capped\nbudget: 50\n---\n\n"), longBody...))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{"capped"}, 16000)
+	if len(resolved.Content) > 100 {
+		t.Fatalf("expected per-skill budget of 50 to cap output, got %d bytes: %q", len(resolved.Content), resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_TinyPerSkillBudgetSkipsOnlyThatSkill(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("tiny/SKILL.md", []byte("---\nname: tiny\nbudget: 1\n---\n\nbody"))
+	skillsFS.WriteFile("normal/SKILL.md", []byte("---\nname: normal\n---\n\nNormal body"))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{"tiny", "normal"}, 16000)
+	if strings.Contains(resolved.Content, "tiny") {
+		t.Fatalf("expected the mis-budgeted skill to be skipped, got %q", resolved.Content)
+	}
+	if !strings.Contains(resolved.Content, "Normal body") {
+		t.Fatalf("expected a later skill to still resolve after a too-small per-skill budget, got %q", resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_DescriptionWithQuotesIsEscaped(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("quoted/SKILL.md", []byte(`---
+name: quoted
+description: Say "hi" & leave
+---
+
+Body`))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{"quoted"}, 0)
+	if strings.Contains(resolved.Content, `description="Say "hi"`) {
+		t.Fatalf("expected embedded quotes to be escaped, got %q", resolved.Content)
+	}
+	if !strings.Contains(resolved.Content, `description="Say &quot;hi&quot; &amp; leave"`) {
+		t.Fatalf("expected escaped attribute value, got %q", resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_MetaSurfacesFrontmatter(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("tooled/SKILL.md", []byte("---\nname: tooled\ndescription: A tooled skill\nmodel: claude-sonnet\nallowed-tools: [\"Bash\"]\n---\n\n# Tooled"))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{"tooled"}, 0)
+	if len(resolved.Meta) != 1 {
+		t.Fatalf("expected 1 meta entry, got %d", len(resolved.Meta))
+	}
+	meta := resolved.Meta[0]
+	if meta.Name != "tooled" || meta.Description != "A tooled skill" || meta.Model != "claude-sonnet" {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+	if len(meta.AllowedTools) != 1 || meta.AllowedTools[0] != "Bash" {
+		t.Fatalf("unexpected allowed-tools: %v", meta.AllowedTools)
+	}
+	if strings.Index(resolved.Content, `description="A tooled skill"`) < 0 {
+		t.Fatalf("expected description attribute on <skill> tag, got %q", resolved.Content)
+	}
+}
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- MemSkillFS / CompositeSkillFS hermetic tests (no real filesystem) ---
+
+func TestDetectProjectSkillsFS_Hermetic(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("go.mod", []byte("module test"))
+
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("golang-base-practices/SKILL.md", []byte("# Go\nUse gofmt."))
+
+	skills := DetectProjectSkillsFS(workFS, skillsFS)
+	if len(skills) != 1 || skills[0] != "golang-base-practices" {
+		t.Fatalf("expected [golang-base-practices], got %v", skills)
+	}
+}
+
+func TestDetectProjectSkillsFS_NotInstalled(t *testing.T) {
+	workFS := NewMemSkillFS()
+	workFS.WriteFile("go.mod", []byte("module test"))
+
+	skills := DetectProjectSkillsFS(workFS, NewMemSkillFS())
+	if len(skills) != 0 {
+		t.Fatalf("expected no skills when nothing installed, got %v", skills)
+	}
+}
+
+func TestResolveSkillContentFS_Hermetic(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	skillsFS.WriteFile("test-skill/SKILL.md", []byte("---\nname: test\n---\n\n# Test Skill\nBody."))
+
+	result := ResolveSkillContentFS(skillsFS, []string{"test-skill"}, 0)
+	if !strings.Contains(result, `<skill name="test-skill">`) {
+		t.Errorf("missing <skill> tag, got %q", result)
+	}
+	if !strings.Contains(result, "# Test Skill") {
+		t.Errorf("missing body, got %q", result)
+	}
+}
+
+func TestCompositeSkillFS_Precedence(t *testing.T) {
+	first := NewMemSkillFS()
+	first.WriteFile("dup-skill/SKILL.md", []byte("first"))
+	second := NewMemSkillFS()
+	second.WriteFile("dup-skill/SKILL.md", []byte("second"))
+
+	composite := NewCompositeSkillFS(first, second)
+	data, err := composite.ReadFile("dup-skill/SKILL.md")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "first" {
+		t.Errorf("expected first layer to win, got %q", data)
+	}
+}
+
+func TestCompositeSkillFS_FallsThroughToNextLayer(t *testing.T) {
+	first := NewMemSkillFS()
+	second := NewMemSkillFS()
+	second.WriteFile("only-in-second/SKILL.md", []byte("second"))
+
+	composite := NewCompositeSkillFS(first, second)
+	data, err := composite.ReadFile("only-in-second/SKILL.md")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("expected fallback to second layer, got %q", data)
+	}
+}
+
+func TestCompositeSkillFS_NotFound(t *testing.T) {
+	composite := NewCompositeSkillFS(NewMemSkillFS(), NewMemSkillFS())
+	if _, err := composite.ReadFile("missing/SKILL.md"); err == nil {
+		t.Fatal("expected error for missing file across all layers")
+	}
+}
@@ -0,0 +1,368 @@
+package executor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// AllowNetworkFetch gates whether SyncSkills may actually call
+// SkillSource.Fetch. It defaults to false; RunSkillsSyncCommand sets it
+// from --allow-network before calling SyncSkills. This is
+// defense-in-depth on top of the structural guarantee that
+// ResolveSkillContent/ResolveSkillsFS never call Fetch at all, so the
+// task hot path stays offline-safe even if SyncSkills is ever invoked
+// directly without going through the CLI's --allow-network check.
+var AllowNetworkFetch = false
+
+// RemoteSkillRef is a parsed "skills:" reference of the form
+// "host/path@rev", e.g. "github.com/org/skills-pack/golang-base-practices@v1.2.0".
+type RemoteSkillRef struct {
+	Host string
+	Path string
+	Rev  string
+}
+
+// String reconstructs the "host/path@rev" form of ref.
+func (r RemoteSkillRef) String() string {
+	return r.Host + "/" + r.Path + "@" + r.Rev
+}
+
+// CachePath returns ref's skill-relative cache path, e.g.
+// ".cache/github.com/org/skills-pack/golang-base-practices@v1.2.0/SKILL.md".
+// It's relative to the same root as the built-in ~/.codex/skills layer,
+// so the existing SkillFS search already reaches it.
+func (r RemoteSkillRef) CachePath() string {
+	return filepath.Join(".cache", r.Host, r.Path+"@"+r.Rev, "SKILL.md")
+}
+
+// ParseRemoteSkillRef parses a "host/path@rev" skill reference. A plain
+// local skill name (no "@") is not a valid remote ref.
+func ParseRemoteSkillRef(s string) (RemoteSkillRef, error) {
+	hostPath, rev, ok := strings.Cut(s, "@")
+	if !ok || rev == "" {
+		return RemoteSkillRef{}, fmt.Errorf("not a remote skill ref (want host/path@rev): %q", s)
+	}
+	parts := strings.SplitN(hostPath, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || !strings.Contains(parts[0], ".") {
+		return RemoteSkillRef{}, fmt.Errorf("not a remote skill ref (want host/path@rev): %q", s)
+	}
+	return RemoteSkillRef{Host: parts[0], Path: parts[1], Rev: rev}, nil
+}
+
+// SkillSource fetches a remote skill bundle into cacheRoot (the same
+// root passed to NewDefaultSkillFS, typically ~/.codex/skills) and
+// returns the SHA-256 of the cached SKILL.md for the lockfile. Fetch
+// always touches the network; only `codeagent skills sync` calls it.
+type SkillSource interface {
+	Fetch(cacheRoot string, ref RemoteSkillRef) (sha256Hex string, err error)
+}
+
+// DefaultSkillSource picks a SkillSource for ref: known git forges are
+// cloned shallowly at the pinned rev, everything else is treated as a
+// plain HTTPS tarball.
+func DefaultSkillSource(ref RemoteSkillRef) SkillSource {
+	switch ref.Host {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		return GitSkillSource{}
+	default:
+		return TarballSkillSource{}
+	}
+}
+
+// GitSkillSource fetches a skill bundle with a shallow `git clone` at
+// the pinned rev, then copies SKILL.md out of the checkout.
+type GitSkillSource struct{}
+
+func (GitSkillSource) Fetch(cacheRoot string, ref RemoteSkillRef) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "codeagent-skill-clone-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	repoURL := "https://" + ref.Host + "/" + ref.Path
+	cmd := exec.Command("git", "clone", "--depth", "1", "--branch", ref.Rev, repoURL, tmpDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git clone %s@%s: %w: %s", repoURL, ref.Rev, err, strings.TrimSpace(string(out)))
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "SKILL.md"))
+	if err != nil {
+		return "", fmt.Errorf("SKILL.md not found in %s@%s: %w", repoURL, ref.Rev, err)
+	}
+	return writeCachedSkill(cacheRoot, ref, data)
+}
+
+// TarballSkillSource fetches a skill bundle from a plain HTTPS tarball
+// (https://<host>/<path>/archive/<rev>.tar.gz) and extracts SKILL.md.
+type TarballSkillSource struct {
+	Client *http.Client
+}
+
+func (t TarballSkillSource) Fetch(cacheRoot string, ref RemoteSkillRef) (string, error) {
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url := "https://" + ref.Host + "/" + ref.Path + "/archive/" + ref.Rev + ".tar.gz"
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch %s: unexpected status %s", url, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("SKILL.md not found in tarball %s", url)
+		}
+		if err != nil {
+			return "", fmt.Errorf("fetch %s: %w", url, err)
+		}
+		if filepath.Base(hdr.Name) != "SKILL.md" {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return "", fmt.Errorf("fetch %s: %w", url, err)
+		}
+		return writeCachedSkill(cacheRoot, ref, data)
+	}
+}
+
+func writeCachedSkill(cacheRoot string, ref RemoteSkillRef, data []byte) (string, error) {
+	dest := filepath.Join(cacheRoot, ref.CachePath())
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return "", err
+	}
+	return sha256Hex(data), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// skillLockEntry records the content-addressed integrity hash for one
+// cached remote skill.
+type skillLockEntry struct {
+	Ref    string `json:"ref"`
+	SHA256 string `json:"sha256"`
+}
+
+// SkillLockfile is the "skills sync" lockfile, persisted next to
+// ParseParallelConfig's config. SyncSkills uses it to skip refs whose
+// cached SKILL.md still matches the recorded hash; skill resolution at
+// task time does not read it.
+type SkillLockfile struct {
+	Skills []skillLockEntry `json:"skills"`
+}
+
+// LoadSkillLockfile reads the lockfile at path, returning an empty
+// lockfile (not an error) if it doesn't exist yet.
+func LoadSkillLockfile(path string) (SkillLockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return SkillLockfile{}, nil
+		}
+		return SkillLockfile{}, err
+	}
+	var lock SkillLockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return SkillLockfile{}, err
+	}
+	return lock, nil
+}
+
+// Save writes the lockfile to path as indented JSON.
+func (l SkillLockfile) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Set records (or updates) ref's content hash.
+func (l *SkillLockfile) Set(ref, sha256Hex string) {
+	for i := range l.Skills {
+		if l.Skills[i].Ref == ref {
+			l.Skills[i].SHA256 = sha256Hex
+			return
+		}
+	}
+	l.Skills = append(l.Skills, skillLockEntry{Ref: ref, SHA256: sha256Hex})
+}
+
+// Get returns ref's recorded content hash, if any.
+func (l SkillLockfile) Get(ref string) (string, bool) {
+	for _, e := range l.Skills {
+		if e.Ref == ref {
+			return e.SHA256, true
+		}
+	}
+	return "", false
+}
+
+// CollectSkillRefs extracts the subset of skill references across all of
+// cfg's tasks that look like remote refs (host/path@rev) rather than
+// local skill names, deduplicated in first-seen order. This is the glue
+// `codeagent skills sync` uses to pre-populate the cache from a parallel
+// config so CI stays hermetic.
+func CollectSkillRefs(cfg *ParallelConfig) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	for _, task := range cfg.Tasks {
+		for _, skill := range task.Skills {
+			if _, err := ParseRemoteSkillRef(skill); err != nil {
+				continue
+			}
+			if seen[skill] {
+				continue
+			}
+			seen[skill] = true
+			refs = append(refs, skill)
+		}
+	}
+	return refs
+}
+
+// SyncSkillsResult reports what `codeagent skills sync` did for each ref.
+type SyncSkillsResult struct {
+	Ref     string
+	Cached  bool // already present with a matching lockfile hash
+	Fetched bool
+	Err     error
+}
+
+// SyncSkills fetches every ref in refs into cacheRoot (typically
+// ~/.codex/skills), recording each one's content hash in the lockfile at
+// lockfilePath. Entries already present with a matching lockfile hash
+// are left alone. This is the only code path allowed to call
+// SkillSource.Fetch — ResolveSkillContent/DetectProjectSkills never do.
+func SyncSkills(refs []string, cacheRoot, lockfilePath string) ([]SyncSkillsResult, error) {
+	lock, err := LoadSkillLockfile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("load lockfile %s: %w", lockfilePath, err)
+	}
+
+	results := make([]SyncSkillsResult, 0, len(refs))
+	for _, raw := range refs {
+		ref, err := ParseRemoteSkillRef(raw)
+		if err != nil {
+			results = append(results, SyncSkillsResult{Ref: raw, Err: err})
+			continue
+		}
+
+		cachedPath := filepath.Join(cacheRoot, ref.CachePath())
+		if data, readErr := os.ReadFile(cachedPath); readErr == nil {
+			if want, ok := lock.Get(raw); ok && want == sha256Hex(data) {
+				results = append(results, SyncSkillsResult{Ref: raw, Cached: true})
+				continue
+			}
+		}
+
+		if !AllowNetworkFetch {
+			results = append(results, SyncSkillsResult{Ref: raw, Err: fmt.Errorf("refusing to fetch %s: AllowNetworkFetch is false", raw)})
+			continue
+		}
+
+		sha, err := DefaultSkillSource(ref).Fetch(cacheRoot, ref)
+		if err != nil {
+			results = append(results, SyncSkillsResult{Ref: raw, Err: err})
+			continue
+		}
+		lock.Set(raw, sha)
+		results = append(results, SyncSkillsResult{Ref: raw, Fetched: true})
+	}
+
+	if err := lock.Save(lockfilePath); err != nil {
+		return results, fmt.Errorf("save lockfile %s: %w", lockfilePath, err)
+	}
+	return results, nil
+}
+
+// RunSkillsSyncCommand implements `codeagent skills sync`: it parses the
+// parallel config at configPath, fetches every remote skill ref it
+// references into ~/.codex/skills/.cache, and prints a PASS/FAIL line per
+// ref. It refuses to run unless allowNetwork is true, since fetching
+// always touches the network.
+func RunSkillsSyncCommand(configPath string, allowNetwork bool) int {
+	if !allowNetwork {
+		fmt.Fprintln(os.Stderr, "skills sync: refusing to fetch without --allow-network")
+		return 1
+	}
+	AllowNetworkFetch = true
+	defer func() { AllowNetworkFetch = false }()
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "skills sync: reading config %s: %v\n", configPath, err)
+		return 1
+	}
+	cfg, err := ParseParallelConfig(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "skills sync: parsing config %s: %v\n", configPath, err)
+		return 1
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "skills sync: resolving home directory: %v\n", err)
+		return 1
+	}
+	cacheRoot := filepath.Join(home, ".codex", "skills")
+	lockfilePath := filepath.Join(filepath.Dir(configPath), "skills-lock.json")
+
+	refs := CollectSkillRefs(cfg)
+	if len(refs) == 0 {
+		fmt.Println("skills sync: no remote skill refs found")
+		return 0
+	}
+
+	results, err := SyncSkills(refs, cacheRoot, lockfilePath)
+	failed := false
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed = true
+			fmt.Printf("FAIL %s: %v\n", r.Ref, r.Err)
+		case r.Cached:
+			fmt.Printf("PASS %s (cached)\n", r.Ref)
+		default:
+			fmt.Printf("PASS %s (fetched)\n", r.Ref)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "skills sync: %v\n", err)
+		failed = true
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteSkillRef(t *testing.T) {
+	ref, err := ParseRemoteSkillRef("github.com/org/skills-pack/golang-base-practices@v1.2.0")
+	if err != nil {
+		t.Fatalf("ParseRemoteSkillRef error: %v", err)
+	}
+	if ref.Host != "github.com" || ref.Path != "org/skills-pack/golang-base-practices" || ref.Rev != "v1.2.0" {
+		t.Fatalf("unexpected ref: %+v", ref)
+	}
+	if got := ref.String(); got != "github.com/org/skills-pack/golang-base-practices@v1.2.0" {
+		t.Fatalf("String roundtrip mismatch: %q", got)
+	}
+}
+
+func TestParseRemoteSkillRef_RejectsLocalNames(t *testing.T) {
+	for _, name := range []string{"golang-base-practices", "my_skill_v2", ""} {
+		if _, err := ParseRemoteSkillRef(name); err == nil {
+			t.Errorf("expected %q to be rejected as a remote ref", name)
+		}
+	}
+}
+
+func TestFindSkillFile_RemoteRefConsultsCache(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	ref, _ := ParseRemoteSkillRef("github.com/org/pack/golang-base-practices@v1.0.0")
+	skillsFS.WriteFile(ref.CachePath(), []byte("# Cached"))
+
+	path := findSkillFile(skillsFS, ref.String())
+	if path != ref.CachePath() {
+		t.Fatalf("expected cache path %q, got %q", ref.CachePath(), path)
+	}
+}
+
+func TestResolveSkillsFS_RemoteRefNotCached_NoNetworkCall(t *testing.T) {
+	AllowNetworkFetch = false
+	skillsFS := NewMemSkillFS()
+	resolved := ResolveSkillsFS(skillsFS, []string{"github.com/org/pack/golang-base-practices@v1.0.0"}, 0)
+	if resolved.Content != "" {
+		t.Fatalf("expected uncached remote ref to resolve to nothing, got %q", resolved.Content)
+	}
+}
+
+func TestResolveSkillsFS_RemoteRefCached(t *testing.T) {
+	skillsFS := NewMemSkillFS()
+	ref, _ := ParseRemoteSkillRef("github.com/org/pack/golang-base-practices@v1.0.0")
+	skillsFS.WriteFile(ref.CachePath(), []byte("---\nname: golang-base-practices\n---\n\n# Go\nUse gofmt."))
+
+	resolved := ResolveSkillsFS(skillsFS, []string{ref.String()}, 0)
+	if !strings.Contains(resolved.Content, "Use gofmt.") {
+		t.Fatalf("expected cached remote skill content, got %q", resolved.Content)
+	}
+}
+
+func TestSkillLockfile_SetGetRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "skills-lock.json")
+
+	lock, err := LoadSkillLockfile(path)
+	if err != nil {
+		t.Fatalf("LoadSkillLockfile: %v", err)
+	}
+	lock.Set("github.com/org/pack/x@v1.0.0", "deadbeef")
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := LoadSkillLockfile(path)
+	if err != nil {
+		t.Fatalf("LoadSkillLockfile (reload): %v", err)
+	}
+	sha, ok := reloaded.Get("github.com/org/pack/x@v1.0.0")
+	if !ok || sha != "deadbeef" {
+		t.Fatalf("expected roundtripped hash, got %q, ok=%v", sha, ok)
+	}
+}
+
+func TestLoadSkillLockfile_MissingFileIsEmpty(t *testing.T) {
+	lock, err := LoadSkillLockfile(filepath.Join(t.TempDir(), "nonexistent-lock.json"))
+	if err != nil {
+		t.Fatalf("expected no error for missing lockfile, got %v", err)
+	}
+	if len(lock.Skills) != 0 {
+		t.Fatalf("expected empty lockfile, got %+v", lock)
+	}
+}
+
+func TestSyncSkills_SkipsAlreadyCachedMatchingHash(t *testing.T) {
+	dir := t.TempDir()
+	cacheRoot := filepath.Join(dir, "skills")
+	lockPath := filepath.Join(dir, "skills-lock.json")
+
+	ref, _ := ParseRemoteSkillRef("github.com/org/pack/x@v1.0.0")
+	cachePath := filepath.Join(cacheRoot, ref.CachePath())
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("# Cached already")
+	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	lock := SkillLockfile{}
+	lock.Set(ref.String(), sha256Hex(data))
+	if err := lock.Save(lockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := SyncSkills([]string{ref.String()}, cacheRoot, lockPath)
+	if err != nil {
+		t.Fatalf("SyncSkills: %v", err)
+	}
+	if len(results) != 1 || !results[0].Cached || results[0].Fetched {
+		t.Fatalf("expected a cache hit with no fetch, got %+v", results)
+	}
+}
+
+func TestSyncSkills_RefusesUncachedFetchWhenNetworkFetchDisallowed(t *testing.T) {
+	AllowNetworkFetch = false
+	dir := t.TempDir()
+
+	ref, _ := ParseRemoteSkillRef("github.com/org/pack/uncached@v1.0.0")
+	results, err := SyncSkills([]string{ref.String()}, filepath.Join(dir, "skills"), filepath.Join(dir, "skills-lock.json"))
+	if err != nil {
+		t.Fatalf("SyncSkills: %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil || results[0].Fetched {
+		t.Fatalf("expected an uncached ref to be refused, not fetched, got %+v", results)
+	}
+}
+
+func TestSyncSkills_InvalidRefReportsError(t *testing.T) {
+	dir := t.TempDir()
+	results, err := SyncSkills([]string{"not-a-remote-ref"}, filepath.Join(dir, "skills"), filepath.Join(dir, "skills-lock.json"))
+	if err != nil {
+		t.Fatalf("SyncSkills: %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("expected an error result for an invalid ref, got %+v", results)
+	}
+}
+
+func TestRunSkillsSyncCommand_RefusesWithoutAllowNetwork(t *testing.T) {
+	if code := RunSkillsSyncCommand("whatever.yaml", false); code != 1 {
+		t.Fatalf("expected exit code 1 without --allow-network, got %d", code)
+	}
+}